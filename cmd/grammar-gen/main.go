@@ -0,0 +1,48 @@
+// Command grammar-gen reads a grammar source file (see grammar.LoadFile) and
+// generates a standalone Go source file exposing its rules, for use with
+// go:generate.
+//
+// Usage:
+//
+//	grammar-gen -pkg mypkg -out rules_gen.go grammar.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gaissmai/grammar"
+)
+
+func main() {
+	pkg := flag.String("pkg", "main", "package name for the generated file")
+	out := flag.String("out", "", "output file (default: stdout)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: grammar-gen -pkg pkg [-out file] <grammar-source-file>")
+		os.Exit(2)
+	}
+
+	g, err := grammar.LoadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := g.Generate(*pkg, w); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,77 @@
+package grammar
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Generate writes a standalone Go source file to w that exposes every
+// compiled rule of g as a package-level variable and an Rx lookup function,
+// with zero runtime dependency on package grammar and no interpolation cost.
+// g must already be compiled.
+//
+// The generated file declares:
+//
+//	var RuleName = regexp.MustCompile(`...`)
+//	func Rx(name string) *regexp.Regexp { ... }
+//
+// This lets the readable, commented grammar stay the source of truth while
+// the generated file ships with the build; see cmd/grammar-gen for a
+// go:generate-friendly command that drives this from a grammar source file
+// loaded with LoadFile.
+func (g *Grammar) Generate(pkg string, w io.Writer) error {
+	if !g.compiled {
+		return fmt.Errorf("grammar %q is not compiled, can't generate", g.name)
+	}
+
+	names := make([]ruleName, 0, len(g.rules))
+	for name := range g.rules {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	fmt.Fprintf(w, "// Code generated from grammar %q by grammar-gen. DO NOT EDIT.\n\n", g.name)
+	fmt.Fprintf(w, "package %s\n\n", pkg)
+	fmt.Fprintf(w, "import \"regexp\"\n\n")
+
+	for _, name := range names {
+		r := g.rules[name]
+		if r.opts.Longest {
+			fmt.Fprintf(w, "var %s = func() *regexp.Regexp {\n\tre := regexp.MustCompile(%s)\n\tre.Longest()\n\treturn re\n}()\n",
+				goIdent(name), goLiteral(r.rx.String()))
+		} else {
+			fmt.Fprintf(w, "var %s = regexp.MustCompile(%s)\n", goIdent(name), goLiteral(r.rx.String()))
+		}
+	}
+
+	fmt.Fprintf(w, "\n// Rx returns the compiled regexp for named rule, or nil if name is unknown.\n")
+	fmt.Fprintf(w, "func Rx(name string) *regexp.Regexp {\n\tswitch name {\n")
+
+	for _, name := range names {
+		fmt.Fprintf(w, "\tcase %q:\n\t\treturn %s\n", name, goIdent(name))
+	}
+
+	fmt.Fprintf(w, "\tdefault:\n\t\treturn nil\n\t}\n}\n")
+
+	return nil
+}
+
+// goIdent turns a (possibly "prefix."-namespaced) rule name into a Go
+// identifier by replacing every "." with "_". The identifier is exported
+// only if the rule name starts with an upper-case letter.
+func goIdent(name ruleName) string {
+	return strings.ReplaceAll(string(name), ".", "_")
+}
+
+// goLiteral renders s as a Go string literal, preferring a raw backtick
+// string for readability and falling back to a quoted one if s contains a backtick.
+func goLiteral(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+
+	return fmt.Sprintf("%q", s)
+}
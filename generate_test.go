@@ -0,0 +1,66 @@
+package grammar_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/grammar"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+
+	checkErr(t, g.Add("NUMBER", `\d+`))
+	checkErr(t, g.Compile())
+
+	var buf strings.Builder
+	if err := g.Generate("mypkg", &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"package mypkg",
+		"var NUMBER = regexp.MustCompile(`\\d+`)",
+		`func Rx(name string) *regexp.Regexp {`,
+		`case "NUMBER":`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Generate() output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateLongest(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+
+	checkErr(t, g.AddWithOptions("AORAB", `a|ab`, grammar.RuleOptions{Longest: true}))
+	checkErr(t, g.Compile())
+
+	var buf strings.Builder
+	if err := g.Generate("mypkg", &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{"re.Longest()", "var AORAB = func() *regexp.Regexp {"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Generate() output for a Longest rule missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateNotCompiled(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+	checkErr(t, g.Add("NUMBER", `\d+`))
+
+	var buf strings.Builder
+	if err := g.Generate("mypkg", &buf); err == nil {
+		t.Error("expected error, grammar is not compiled")
+	}
+}
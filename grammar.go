@@ -53,7 +53,10 @@ var (
 
 	// regexps for interpolation.
 	rxGrepSubRuleRelaxed = regexp.MustCompile(Trim(`\$\{ (?P<SUBRULE> [^{}]+ ) \}`))
-	rxMatchSubRuleStrict = regexp.MustCompile(Trim(`^ [a-zA-Z_] \w* $`))
+
+	// a rulename, optionally namespaced by one or more "prefix." segments
+	// from Import (composing an already-imported grammar nests prefixes).
+	rxMatchSubRuleStrict = regexp.MustCompile(Trim(`^ [a-zA-Z_] \w* (?: \. [a-zA-Z_] \w* )* $`))
 )
 
 // Trim removes all comments and whitespace from string.
@@ -71,42 +74,78 @@ func Trim(s string) string {
 // Grammar is a container for related and maybe dependent rules.
 // Subrules are string interpolated in other rules before compiling to regexp.
 type Grammar struct {
-	name     string             // give the grammar a name
-	rules    map[ruleName]*rule // the map of all rules, the rule name is the key
-	compiled bool               // all dependencies are resolved und all rules are compiled
+	name     string              // give the grammar a name
+	rules    map[ruleName]*rule  // the map of all rules, the rule name is the key
+	compiled bool                // all dependencies are resolved und all rules are compiled
+	imports  map[string]*Grammar // imported grammars, keyed by their namespace prefix
+
+	parseEnabled bool                // wrap interpolation sites in capture groups for Parse/ParseAll, see NewParseable
+	captureSeq   int                 // monotonic counter, makes interpolation capture names unique
+	captures     map[string]ruleName // capture group name -> originating subrule, for Parse
 }
 
 // rule is a container for a regexp, based on a raw string, ?trimmed?,
 // parsed and interpolated with regexp strings from other rules in same grammar.
 type rule struct {
-	name     ruleName       // give the rule a name
-	subrules []ruleName     // a slice of all ${SUBRULE} the rule depends on
-	pattern  string         // the input, trimmed or unaltered
-	final    string         // all subrules interpolated
-	rx       *regexp.Regexp // the compiled regexp
+	name             ruleName       // give the rule a name
+	subrules         []ruleName     // a slice of all ${SUBRULE} the rule depends on
+	pattern          string         // the input, trimmed or unaltered
+	final            string         // all subrules interpolated, with flags and anchors applied
+	interpolateFinal string         // like final, but without anchors, for splicing into a parent rule
+	rx               *regexp.Regexp // the compiled regexp
+	opts             RuleOptions    // flags and anchoring declared for this rule
 }
 
-// New initializes a new grammar.
+// New initializes a new grammar. Rules in a grammar created with New compile
+// to plain regexps, exactly as written: no extra capture groups are added,
+// so Rx's output matches the input pattern one-for-one. Use NewParseable
+// instead if the grammar needs Parse or ParseAll.
 func New(name string) *Grammar {
 	return &Grammar{
-		name:  name,
-		rules: make(map[ruleName]*rule),
+		name:     name,
+		rules:    make(map[ruleName]*rule),
+		captures: make(map[string]ruleName),
 	}
 }
 
+// NewParseable is like New, but additionally wraps every ${SUBRULE}
+// interpolation site in its own named capture group, so the grammar's rules
+// can later be used with Parse or ParseAll to reconstruct a parse tree. This
+// adds a capture group per interpolation site and changes the compiled
+// regexp's capture-group count and order compared to New, so only ask for it
+// when Parse support is actually needed.
+func NewParseable(name string) *Grammar {
+	g := New(name)
+	g.parseEnabled = true
+
+	return g
+}
+
 // Add rule to grammar, returns error if rule with same name already exists
 // or grammar is already compiled. The pattern string gets trimmed.
 func (g *Grammar) Add(name string, pattern string) error {
-	return g.add(ruleName(name), Trim(pattern))
+	return g.add(ruleName(name), Trim(pattern), RuleOptions{})
 }
 
 // AddVerbatim is similar to Add, but no trimming takes place.
 // Use this method if whitespace is significant.
 func (g *Grammar) AddVerbatim(name string, pattern string) error {
-	return g.add(ruleName(name), pattern)
+	return g.add(ruleName(name), pattern, RuleOptions{})
+}
+
+// AddWithOptions is similar to Add, but additionally declares regexp flags
+// and anchoring for the rule via opts, see RuleOptions.
+func (g *Grammar) AddWithOptions(name string, pattern string, opts RuleOptions) error {
+	return g.add(ruleName(name), Trim(pattern), opts)
+}
+
+// AddVerbatimWithOptions is similar to AddVerbatim, but additionally declares
+// regexp flags and anchoring for the rule via opts, see RuleOptions.
+func (g *Grammar) AddVerbatimWithOptions(name string, pattern string, opts RuleOptions) error {
+	return g.add(ruleName(name), pattern, opts)
 }
 
-func (g *Grammar) add(ruleName ruleName, pattern string) error {
+func (g *Grammar) add(ruleName ruleName, pattern string, opts RuleOptions) error {
 	if !ruleName.isValid() {
 		return fmt.Errorf("grammar %q, rulename %q not allowed", g.name, ruleName)
 	}
@@ -119,7 +158,7 @@ func (g *Grammar) add(ruleName ruleName, pattern string) error {
 		return fmt.Errorf("grammar %q, rule with name %q already exists", g.name, ruleName)
 	}
 
-	r := &rule{name: ruleName, pattern: pattern}
+	r := &rule{name: ruleName, pattern: pattern, opts: opts}
 
 	r.subrules = findSubrules(r)
 	for _, subName := range r.subrules {
@@ -162,14 +201,22 @@ func (g *Grammar) Compile() error {
 
 		replace := replaceMap{}
 
-		// build replace map: replace ${SUBRULE} with final string of SUBRULE
+		// build replace map: replace ${SUBRULE} with interpolateFinal string of
+		// SUBRULE (flags applied, anchors stripped so they can't anchor the
+		// interpolation site instead of the subrule's own match). Only wrap in
+		// a flag-reset group when the subrule actually declares flags, so a
+		// subrule without RuleOptions interpolates exactly as before.
 		for _, subruleName := range rule.subrules {
 			subrule := g.rules[subruleName]
-			replace[subruleName] = subrule.final
+			final := subrule.interpolateFinal
+			if subrule.opts.hasFlags() {
+				final = resetFlagScope(final)
+			}
+			replace[subruleName] = final
 		}
 
 		// and now replace the subrules and compile the pattern to regexp
-		if err := rule.compile(replace); err != nil {
+		if err := rule.compile(g, replace); err != nil {
 			return fmt.Errorf("grammar %q, %w", g.name, err)
 		}
 	}
@@ -180,18 +227,38 @@ func (g *Grammar) Compile() error {
 }
 
 // compile the regexp for rule, but before replace all subrules with their final string.
-func (r *rule) compile(replace replaceMap) error {
+//
+// If g.parseEnabled, every interpolation site is additionally wrapped in its
+// own uniquely named capture group (?P<__gram_N>...), and the group name is
+// recorded in g.captures so that Parse can later walk the capture spans and
+// reconstruct the parse tree. Plain grammars created with New skip this
+// wrapping, so their compiled regexp matches the pattern one-for-one.
+func (r *rule) compile(g *Grammar, replace replaceMap) error {
 	if r.rx != nil {
 		panic("logic error, rule is already compiled")
 	}
 
-	// replace the subrules with their final string
+	// replace the subrules with their final string. When the grammar is
+	// parseable, wrap each occurrence in its own uniquely named capture group
+	// so repeated use of the same subrule doesn't collide and Parse can tell
+	// them apart.
 	s := r.pattern
 	for subrule, final := range replace {
-		rx := regexp.MustCompile(`\Q${` + string(subrule) + `}\E`)
-		s = rx.ReplaceAllLiteralString(s, final)
+		placeholder := regexp.MustCompile(`\Q${` + string(subrule) + `}\E`)
+		s = placeholder.ReplaceAllStringFunc(s, func(string) string {
+			if !g.parseEnabled {
+				return final
+			}
+
+			g.captureSeq++
+			capName := fmt.Sprintf("__gram_%d", g.captureSeq)
+			g.captures[capName] = subrule
+
+			return "(?P<" + capName + ">" + final + ")"
+		})
 	}
-	r.final = s
+	r.interpolateFinal = applyFlags(r.opts, s)
+	r.final = applyAnchors(r.opts, r.interpolateFinal)
 
 	var err error
 	r.rx, err = regexp.Compile(r.final)
@@ -199,6 +266,10 @@ func (r *rule) compile(replace replaceMap) error {
 		return fmt.Errorf("regexp compilation of rule %q, %w", r.name, err)
 	}
 
+	if r.opts.Longest {
+		r.rx.Longest()
+	}
+
 	return nil
 }
 
@@ -0,0 +1,104 @@
+package grammar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Import pulls in every rule of other under the namespace prefix, so e.g.
+// importing a grammar as prefix "net." makes its rules resolvable as
+// ${net.RuleName}. prefix must end in "." and not collide with a prefix
+// already used on g. other may be compiled or not; importing g into itself,
+// directly or transitively, is rejected as a cyclic import.
+func (g *Grammar) Import(prefix string, other *Grammar) error {
+	if g.compiled {
+		return fmt.Errorf("grammar %q is already compiled, can't import %q", g.name, other.name)
+	}
+
+	if !strings.HasSuffix(prefix, ".") {
+		return fmt.Errorf("grammar %q, import prefix %q must end in %q", g.name, prefix, ".")
+	}
+
+	if g == other || other.importsTransitively(g) {
+		return fmt.Errorf("grammar %q, importing %q would be cyclic", g.name, other.name)
+	}
+
+	if _, ok := g.imports[prefix]; ok {
+		return fmt.Errorf("grammar %q, import prefix %q already used", g.name, prefix)
+	}
+
+	// validate and build every renamed rule into a scratch map first, so a
+	// failing Import never leaves other's rules partially merged into g.
+	scratch := make(map[ruleName]*rule, len(other.rules))
+
+	for name, src := range other.rules {
+		newName := ruleName(prefix + string(name))
+
+		if !newName.isValid() {
+			return fmt.Errorf("grammar %q, importing %q, rulename %q not allowed", g.name, other.name, newName)
+		}
+
+		if _, ok := g.rules[newName]; ok {
+			return fmt.Errorf("grammar %q, importing %q, rule %q already exists", g.name, other.name, newName)
+		}
+
+		newRule := &rule{
+			name:    newName,
+			pattern: rewriteSubruleRefs(src.pattern, other.rules, prefix),
+			opts:    src.opts,
+		}
+		newRule.subrules = findSubrules(newRule)
+
+		for _, subName := range newRule.subrules {
+			if !subName.isValid() {
+				return fmt.Errorf("grammar %q, importing %q, rule %q, wrong subrule name %q", g.name, other.name, newName, subName)
+			}
+
+			if subName == newRule.name {
+				return fmt.Errorf("grammar %q, importing %q, rule %q is self referencing", g.name, other.name, newName)
+			}
+		}
+
+		scratch[newName] = newRule
+	}
+
+	// every renamed rule validated, merge atomically
+	for name, r := range scratch {
+		g.rules[name] = r
+	}
+
+	if g.imports == nil {
+		g.imports = make(map[string]*Grammar)
+	}
+	g.imports[prefix] = other
+
+	return nil
+}
+
+// importsTransitively reports whether g imports target, directly or
+// through a chain of imports.
+func (g *Grammar) importsTransitively(target *Grammar) bool {
+	for _, imported := range g.imports {
+		if imported == target || imported.importsTransitively(target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rewriteSubruleRefs rewrites every ${SUBRULE} reference in pattern that
+// names a rule of rules to ${prefix+SUBRULE}, so an imported rule keeps
+// resolving its own subrules after being namespaced.
+func rewriteSubruleRefs(pattern string, rules map[ruleName]*rule, prefix string) string {
+	return rxGrepSubRuleRelaxed.ReplaceAllStringFunc(pattern, func(match string) string {
+		m := rxGrepSubRuleRelaxed.FindStringSubmatch(match)
+		name := ruleName(m[1])
+
+		if _, ok := rules[name]; !ok {
+			return match
+		}
+
+		return "${" + prefix + string(name) + "}"
+	})
+}
@@ -0,0 +1,144 @@
+package grammar_test
+
+import (
+	"testing"
+
+	"github.com/gaissmai/grammar"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	ip := grammar.New("IP")
+	checkErr(t, ip.Add("LikeIPv4", `\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}`))
+	checkErr(t, ip.Compile())
+
+	g := grammar.New("TEST")
+	checkErr(t, g.Import("net.", ip))
+	checkErr(t, g.Add("HOST", `${net.LikeIPv4} : \d+`))
+	checkErr(t, g.Compile())
+
+	rx, err := g.Rx("HOST")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rx.MatchString("1.2.3.4:8080") {
+		t.Errorf("Rx(HOST).MatchString(%q) = false, want true", "1.2.3.4:8080")
+	}
+}
+
+func TestImportPrefixCollision(t *testing.T) {
+	t.Parallel()
+
+	ip := grammar.New("IP")
+	checkErr(t, ip.Add("LikeIPv4", `\d+`))
+	checkErr(t, ip.Compile())
+
+	g := grammar.New("TEST")
+	checkErr(t, g.Import("net.", ip))
+
+	if err := g.Import("net.", ip); err == nil {
+		t.Error("expected error, prefix already used")
+	}
+}
+
+// TestImportPrefixCollisionDistinctGrammars pins down the prefix-collision
+// check itself: re-importing the same grammar under an already-used prefix
+// also fails on "rule already exists", which would pass even if the prefix
+// check were missing. Here the second grammar has disjoint rule names, so
+// only the prefix check can catch the collision.
+func TestImportPrefixCollisionDistinctGrammars(t *testing.T) {
+	t.Parallel()
+
+	ip := grammar.New("IP")
+	checkErr(t, ip.Add("LikeIPv4", `\d+`))
+	checkErr(t, ip.Compile())
+
+	other := grammar.New("OTHER")
+	checkErr(t, other.Add("DISJOINT", `x+`))
+	checkErr(t, other.Compile())
+
+	g := grammar.New("TEST")
+	checkErr(t, g.Import("net.", ip))
+
+	if err := g.Import("net.", other); err == nil {
+		t.Error("expected error, prefix already used")
+	}
+}
+
+func TestImportCyclic(t *testing.T) {
+	t.Parallel()
+
+	a := grammar.New("A")
+	checkErr(t, a.Add("ONE", `\d+`))
+
+	b := grammar.New("B")
+	checkErr(t, b.Add("TWO", `\d+`))
+
+	checkErr(t, a.Import("b.", b))
+
+	if err := b.Import("a.", a); err == nil {
+		t.Error("expected error, cyclic import")
+	}
+}
+
+func TestImportNested(t *testing.T) {
+	t.Parallel()
+
+	ip := grammar.New("IP")
+	checkErr(t, ip.Add("LikeIPv4", `\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}`))
+	checkErr(t, ip.Compile())
+
+	mid := grammar.New("MID")
+	checkErr(t, mid.Import("net.", ip))
+
+	top := grammar.New("TOP")
+	checkErr(t, top.Import("outer.", mid))
+	checkErr(t, top.Add("HOST", `${outer.net.LikeIPv4} : \d+`))
+	checkErr(t, top.Compile())
+
+	rx, err := top.Rx("HOST")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rx.MatchString("1.2.3.4:8080") {
+		t.Errorf("Rx(HOST).MatchString(%q) = false, want true", "1.2.3.4:8080")
+	}
+}
+
+func TestImportAtomicOnFailure(t *testing.T) {
+	t.Parallel()
+
+	other := grammar.New("OTHER")
+	checkErr(t, other.Add("ALPHA", `a`))
+	checkErr(t, other.Add("BETA", `b`))
+	checkErr(t, other.Compile())
+
+	g := grammar.New("TEST")
+	// pre-existing rule collides with one of other's renamed rules
+	checkErr(t, g.Add("net.BETA", `whatever`))
+
+	if err := g.Import("net.", other); err == nil {
+		t.Fatal("expected error, rule collision")
+	}
+
+	// if Import had partially merged other's rules before failing, adding
+	// "net.ALPHA" again here would fail with "already exists"
+	if err := g.Add("net.ALPHA", `a`); err != nil {
+		t.Errorf("Import left net.ALPHA merged into g after failing, Import is not atomic: %v", err)
+	}
+}
+
+func TestImportInvalidPrefix(t *testing.T) {
+	t.Parallel()
+
+	other := grammar.New("OTHER")
+	checkErr(t, other.Add("ONE", `\d+`))
+
+	g := grammar.New("TEST")
+	if err := g.Import("net", other); err == nil {
+		t.Error("expected error, prefix must end in a dot")
+	}
+}
@@ -0,0 +1,169 @@
+package grammar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// rxRuleHeader matches a rule header line of the grammar source-file format:
+//
+//	NAME := <pattern>   // trimmed rule, comments and whitespace stripped
+//	NAME =! <pattern>   // verbatim rule, taken as-is
+var rxRuleHeader = regexp.MustCompile(`^(\S+)\s*(:=|=!)\s*(.*)$`)
+
+// rxErrRuleName extracts the rule name from a Compile error message that
+// quotes it as `rule "NAME"`. A cyclic-dependency error names no single
+// rule this way and does not match.
+var rxErrRuleName = regexp.MustCompile(`rule "([^"]+)"`)
+
+// pos is the line:col of a rule header within a loaded grammar source.
+type pos struct {
+	line, col int
+}
+
+// LoadString parses a grammar source in src and returns the compiled Grammar
+// named name. The source format is a sequence of rules:
+//
+//	// a comment, only recognized between rules
+//	NAME := <pattern>
+//	        <pattern continues on following lines...>
+//
+//	OTHER =! <verbatim pattern>
+//
+// A rule introduced with ":=" is added with Add (comments and whitespace are
+// trimmed from its pattern); one introduced with "=!" is added with
+// AddVerbatim. A pattern continues over following lines until a blank line
+// or the next "NAME :=" / "NAME =!" header. LoadString calls Compile before
+// returning, so the result is ready to use with Rx. Errors that concern one
+// rule, including regexp compilation errors, are reported as
+// "name:line:col: ..."; a cyclic dependency between rules is reported as
+// "name: ..." without a line or column.
+func LoadString(name string, src string) (*Grammar, error) {
+	return load(name, name, src)
+}
+
+// LoadFile is like LoadString, but reads the grammar source from the file at
+// path and derives the grammar name from its base name, stripped of extension.
+func LoadFile(path string) (*Grammar, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	return load(name, path, string(b))
+}
+
+// load parses src as a grammar source file, file is the name used for
+// error positions (the path for LoadFile, the grammar name for LoadString).
+func load(grammarName, file, src string) (*Grammar, error) {
+	g := New(grammarName)
+
+	positions := make(map[ruleName]pos)
+
+	var (
+		curName     ruleName
+		curVerbatim bool
+		curPattern  strings.Builder
+		curPos      pos
+		open        bool
+	)
+
+	flush := func() error {
+		if !open {
+			return nil
+		}
+
+		p := curPos
+		pattern := strings.TrimSuffix(curPattern.String(), "\n")
+
+		var err error
+		if curVerbatim {
+			err = g.AddVerbatim(string(curName), pattern)
+		} else {
+			err = g.Add(string(curName), pattern)
+		}
+
+		open = false
+		curPattern.Reset()
+
+		if err != nil {
+			return fmt.Errorf("%s:%d:%d: %w", file, p.line, p.col, err)
+		}
+
+		positions[curName] = p
+
+		return nil
+	}
+
+	startRule := func(lineNo int, line string, m []string) {
+		curName = ruleName(m[1])
+		curVerbatim = m[2] == "=!"
+		curPattern.WriteString(m[3])
+		curPattern.WriteByte('\n')
+		curPos = pos{line: lineNo, col: strings.Index(line, m[1]) + 1}
+		open = true
+	}
+
+	for i, line := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		if !open {
+			switch {
+			case trimmed == "", strings.HasPrefix(trimmed, "//"):
+				continue
+			}
+
+			m := rxRuleHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf(`%s:%d:1: expected rule header "NAME := ..." or "NAME =! ...", got %q`, file, lineNo, line)
+			}
+
+			startRule(lineNo, line, m)
+
+			continue
+		}
+
+		if trimmed == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if m := rxRuleHeader.FindStringSubmatch(line); m != nil {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			startRule(lineNo, line, m)
+
+			continue
+		}
+
+		curPattern.WriteString(line)
+		curPattern.WriteByte('\n')
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if err := g.Compile(); err != nil {
+		if m := rxErrRuleName.FindStringSubmatch(err.Error()); m != nil {
+			if p, ok := positions[ruleName(m[1])]; ok {
+				return nil, fmt.Errorf("%s:%d:%d: %w", file, p.line, p.col, err)
+			}
+		}
+
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return g, nil
+}
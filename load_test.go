@@ -0,0 +1,75 @@
+package grammar_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gaissmai/grammar"
+)
+
+func TestLoadString(t *testing.T) {
+	t.Parallel()
+	src := `
+// a NUMBER, possibly with a sign
+NUMBER := [+-]?           // optional sign
+          \d+             // digits
+
+// verbatim rule, no trimming
+RAW =! ^\Qhello\E$
+`
+
+	g, err := grammar.LoadString("TEST", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rx, err := g.Rx("NUMBER")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rx.MatchString("-42") {
+		t.Errorf("Rx(NUMBER).MatchString(%q) = false, want true", "-42")
+	}
+
+	rx, err = g.Rx("RAW")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rx.String() != `^\Qhello\E$` {
+		t.Errorf("Rx(RAW) = %s, want verbatim pattern unchanged", rx)
+	}
+}
+
+func TestLoadStringBadHeader(t *testing.T) {
+	t.Parallel()
+	if _, err := grammar.LoadString("TEST", "not a header"); err == nil {
+		t.Error("expected error, malformed rule header")
+	}
+}
+
+func TestLoadStringCompileError(t *testing.T) {
+	t.Parallel()
+	if _, err := grammar.LoadString("TEST", "ONE := ^(\n"); err == nil {
+		t.Error("expected error, invalid regexp")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "number.grammar")
+	if err := os.WriteFile(path, []byte("NUMBER := \\d+\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := grammar.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.Rx("NUMBER"); err != nil {
+		t.Fatal(err)
+	}
+}
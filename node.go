@@ -0,0 +1,150 @@
+package grammar
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Node is one node of the hierarchical parse tree returned by Grammar.Parse
+// and Grammar.ParseAll. It mirrors the nesting of ${SUBRULE} interpolations:
+// a Node's Children are the subrule matches that occur within its own
+// matched span, ordered as they occur in the source.
+//
+// Limitation: when a subrule is interpolated inside a repetition, e.g.
+// `(?: ${DIGIT} )+`, Go's regexp only keeps the span of the last repetition
+// in its submatch array, so only the last occurrence shows up as a Child;
+// earlier occurrences are silently not represented in the tree. This is an
+// inherent limitation of reconstructing a parse tree from regexp capture
+// groups, not specific to any one rule.
+type Node struct {
+	Name     string // name of the rule (or subrule) this node matched
+	Parent   *Node  // nil for the root node
+	Children []*Node
+
+	Start, End int // byte offsets of the matched span within the original input
+	Line, Col  int // 1-based line and column of Start
+}
+
+// Text returns the substring of input that produced n.
+func (n *Node) Text(input string) string {
+	return input[n.Start:n.End]
+}
+
+// Parse runs the compiled rule named ruleName against input and reconstructs
+// a hierarchical parse tree from the nested ${SUBRULE} capture groups
+// recorded at Compile time. The match must span the whole of input, returning
+// an error otherwise; use ParseAll to find repeated or partial matches instead.
+// g must have been created with NewParseable, otherwise no capture groups
+// were recorded and Parse returns an error.
+//
+// See the Node doc comment for the repeated-subrule limitation: a subrule
+// interpolated inside a repetition (e.g. `(?: ${DIGIT} )+`) only yields its
+// last occurrence as a Child.
+func (g *Grammar) Parse(ruleName string, input string) (*Node, error) {
+	if !g.parseEnabled {
+		return nil, fmt.Errorf("grammar %q was created with New, not NewParseable, Parse is not available", g.name)
+	}
+
+	rx, err := g.Rx(ruleName)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := rx.FindStringSubmatchIndex(input)
+	if idx == nil {
+		return nil, fmt.Errorf("grammar %q, rule %q does not match %q", g.name, ruleName, input)
+	}
+
+	if idx[0] != 0 || idx[1] != len(input) {
+		return nil, fmt.Errorf("grammar %q, rule %q matches only %q, not the whole input %q",
+			g.name, ruleName, input[idx[0]:idx[1]], input)
+	}
+
+	return g.buildTree(ruleName, rx, idx, input), nil
+}
+
+// ParseAll is similar to Parse but returns every non-overlapping match of
+// rule ruleName in input, without requiring a match to span the whole input.
+// g must have been created with NewParseable, see Parse.
+// See the Node doc comment for the repeated-subrule limitation.
+func (g *Grammar) ParseAll(ruleName string, input string) ([]*Node, error) {
+	if !g.parseEnabled {
+		return nil, fmt.Errorf("grammar %q was created with New, not NewParseable, ParseAll is not available", g.name)
+	}
+
+	rx, err := g.Rx(ruleName)
+	if err != nil {
+		return nil, err
+	}
+
+	allIdx := rx.FindAllStringSubmatchIndex(input, -1)
+	if allIdx == nil {
+		return nil, fmt.Errorf("grammar %q, rule %q does not match %q", g.name, ruleName, input)
+	}
+
+	nodes := make([]*Node, 0, len(allIdx))
+	for _, idx := range allIdx {
+		nodes = append(nodes, g.buildTree(ruleName, rx, idx, input))
+	}
+
+	return nodes, nil
+}
+
+// buildTree walks the ordered capture spans of a single match and
+// reconstructs the parse tree: a capture whose span is contained inside
+// another becomes its child, in source order.
+func (g *Grammar) buildTree(root string, rx *regexp.Regexp, idx []int, input string) *Node {
+	names := rx.SubexpNames()
+
+	rootNode := newNode(root, idx[0], idx[1], input)
+	stack := []*Node{rootNode}
+
+	for i := 1; i < len(names); i++ {
+		start, end := idx[2*i], idx[2*i+1]
+		if start < 0 {
+			continue // capture group did not participate in this match
+		}
+
+		subName, ok := g.captures[names[i]]
+		if !ok {
+			continue // a named group from the user's own pattern, not ours
+		}
+
+		// pop until we find the innermost still-open ancestor containing this span
+		for len(stack) > 1 && (start < stack[len(stack)-1].Start || end > stack[len(stack)-1].End) {
+			stack = stack[:len(stack)-1]
+		}
+
+		node := newNode(string(subName), start, end, input)
+		parent := stack[len(stack)-1]
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+
+		stack = append(stack, node)
+	}
+
+	return rootNode
+}
+
+func newNode(name string, start, end int, input string) *Node {
+	line, col := lineCol(input, start)
+	return &Node{Name: name, Start: start, End: end, Line: line, Col: col}
+}
+
+// lineCol computes the 1-based line and column of byte offset in input.
+func lineCol(input string, offset int) (line, col int) {
+	line, col = 1, 1
+
+	for _, r := range input[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+
+			continue
+		}
+
+		col++
+	}
+
+	return line, col
+}
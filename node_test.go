@@ -0,0 +1,123 @@
+package grammar_test
+
+import (
+	"testing"
+
+	"github.com/gaissmai/grammar"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	g := grammar.NewParseable("TEST")
+
+	checkErr(t, g.Add("DIGITS", `\d+`))
+	checkErr(t, g.Add("PAIR", `${DIGITS} , ${DIGITS}`))
+	checkErr(t, g.Compile())
+
+	root, err := g.Parse("PAIR", "12,345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.Name != "PAIR" || root.Text("12,345") != "12,345" {
+		t.Fatalf("root = %+v, want PAIR spanning the whole input", root)
+	}
+
+	if len(root.Children) != 2 {
+		t.Fatalf("got %d children, want 2", len(root.Children))
+	}
+
+	if got, want := root.Children[0].Text("12,345"), "12"; got != want {
+		t.Errorf("first child text = %q, want %q", got, want)
+	}
+
+	if got, want := root.Children[1].Text("12,345"), "345"; got != want {
+		t.Errorf("second child text = %q, want %q", got, want)
+	}
+
+	for _, child := range root.Children {
+		if child.Name != "DIGITS" {
+			t.Errorf("child.Name = %q, want %q", child.Name, "DIGITS")
+		}
+
+		if child.Parent != root {
+			t.Error("child.Parent is not root")
+		}
+	}
+}
+
+func TestParseNotWholeInput(t *testing.T) {
+	t.Parallel()
+	g := grammar.NewParseable("TEST")
+
+	checkErr(t, g.Add("DIGITS", `\d+`))
+	checkErr(t, g.Compile())
+
+	if _, err := g.Parse("DIGITS", "123abc"); err == nil {
+		t.Error("expected error, match doesn't span the whole input")
+	}
+}
+
+// TestParseRepeatedSubruleLosesEarlierOccurrences pins down a known
+// limitation: Go's regexp only keeps the last repetition's span in its
+// submatch array, so a subrule interpolated inside a repetition only
+// surfaces as a single Child, not one per occurrence. See the Node doc
+// comment.
+func TestParseRepeatedSubruleLosesEarlierOccurrences(t *testing.T) {
+	t.Parallel()
+	g := grammar.NewParseable("TEST")
+
+	checkErr(t, g.Add("DIGIT", `\d`))
+	checkErr(t, g.Add("DIGITS", `(?:${DIGIT})+`))
+	checkErr(t, g.Compile())
+
+	root, err := g.Parse("DIGITS", "12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.Text("12345") != "12345" {
+		t.Fatalf("root.Text() = %q, want %q", root.Text("12345"), "12345")
+	}
+
+	if len(root.Children) != 1 {
+		t.Fatalf("got %d children, want 1 (only the last repetition survives)", len(root.Children))
+	}
+
+	if got, want := root.Children[0].Text("12345"), "5"; got != want {
+		t.Errorf("surviving child text = %q, want %q (only the last occurrence is kept)", got, want)
+	}
+}
+
+func TestParseNotParseable(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+
+	checkErr(t, g.Add("DIGITS", `\d+`))
+	checkErr(t, g.Compile())
+
+	if _, err := g.Parse("DIGITS", "123"); err == nil {
+		t.Error("expected error, grammar was created with New, not NewParseable")
+	}
+
+	if _, err := g.ParseAll("DIGITS", "123"); err == nil {
+		t.Error("expected error, grammar was created with New, not NewParseable")
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	t.Parallel()
+	g := grammar.NewParseable("TEST")
+
+	checkErr(t, g.Add("DIGITS", `\d+`))
+	checkErr(t, g.Compile())
+
+	nodes, err := g.ParseAll("DIGITS", "12 a 345 b 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 3 {
+		t.Fatalf("got %d matches, want 3", len(nodes))
+	}
+}
@@ -0,0 +1,73 @@
+package grammar
+
+// RuleOptions declares per-rule regexp flags and anchoring, so a rule author
+// doesn't have to remember to embed them by hand in the pattern (e.g.
+// `(?i:...)` or `^...$`).
+type RuleOptions struct {
+	IgnoreCase bool // (?i) case-insensitive match
+	DotNL      bool // (?s) let . match \n too
+	Multiline  bool // (?m) let ^ and $ match at line boundaries
+	Ungreedy   bool // (?U) swap the meaning of greedy and non-greedy repetition
+
+	Anchored  bool // wrap the pattern as ^(?:...)
+	FullMatch bool // wrap the pattern as ^(?:...)$, implies Anchored
+
+	Longest bool // call Regexp.Longest() on the compiled rule
+}
+
+// hasFlags reports whether opts sets any of the leading (?flags).
+func (opts RuleOptions) hasFlags() bool {
+	return opts.IgnoreCase || opts.Multiline || opts.DotNL || opts.Ungreedy
+}
+
+// applyFlags wraps pattern in a leading (?flags) prefix per opts. This is
+// the part of a rule's options that stays valid when the rule is spliced
+// into a parent as a subrule.
+func applyFlags(opts RuleOptions, pattern string) string {
+	var flags string
+
+	if opts.IgnoreCase {
+		flags += "i"
+	}
+
+	if opts.Multiline {
+		flags += "m"
+	}
+
+	if opts.DotNL {
+		flags += "s"
+	}
+
+	if opts.Ungreedy {
+		flags += "U"
+	}
+
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	return pattern
+}
+
+// applyAnchors wraps pattern in ^ / $ per opts. Anchors only make sense
+// around a rule's own top-level match, so this must never be applied to
+// the string used to interpolate the rule into a parent as a subrule,
+// else the anchor would wrongly require the parent's match to start or
+// end at that interpolation site.
+func applyAnchors(opts RuleOptions, pattern string) string {
+	switch {
+	case opts.FullMatch:
+		pattern = "^(?:" + pattern + ")$"
+	case opts.Anchored:
+		pattern = "^(?:" + pattern + ")"
+	}
+
+	return pattern
+}
+
+// resetFlagScope wraps final in a non-capturing group with the regexp flag
+// scope reset to its default, so a subrule's own (?i)-style flags can't leak
+// past this interpolation site into the rest of the enclosing rule, and vice versa.
+func resetFlagScope(final string) string {
+	return "(?-imsU:" + final + ")"
+}
@@ -0,0 +1,70 @@
+package grammar_test
+
+import (
+	"testing"
+
+	"github.com/gaissmai/grammar"
+)
+
+func TestAddWithOptionsFlags(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+
+	checkErr(t, g.AddWithOptions("WORD", `hello`, grammar.RuleOptions{IgnoreCase: true, FullMatch: true}))
+	checkErr(t, g.Compile())
+
+	rx, err := g.Rx("WORD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, in := range []string{"hello", "HELLO", "HeLLo"} {
+		if !rx.MatchString(in) {
+			t.Errorf("Rx(WORD).MatchString(%q) = false, want true", in)
+		}
+	}
+
+	if rx.MatchString("say hello") {
+		t.Errorf("Rx(WORD).MatchString(%q) = true, want false, FullMatch should anchor", "say hello")
+	}
+}
+
+func TestAddWithOptionsFlagsDontLeak(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+
+	checkErr(t, g.AddWithOptions("WORD", `hello`, grammar.RuleOptions{IgnoreCase: true}))
+	checkErr(t, g.Add("SENTENCE", `${WORD}\sworld`))
+	checkErr(t, g.Compile())
+
+	rx, err := g.Rx("SENTENCE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rx.MatchString("HELLO world") {
+		t.Errorf("Rx(SENTENCE).MatchString(%q) = false, want true", "HELLO world")
+	}
+
+	if rx.MatchString("hello WORLD") {
+		t.Errorf("Rx(SENTENCE).MatchString(%q) = true, want false, WORD's (?i) must not leak into the rest of SENTENCE", "hello WORLD")
+	}
+}
+
+func TestAddWithOptionsAnchorsDontLeak(t *testing.T) {
+	t.Parallel()
+	g := grammar.New("TEST")
+
+	checkErr(t, g.AddWithOptions("WORD", `hello`, grammar.RuleOptions{FullMatch: true}))
+	checkErr(t, g.Add("SENTENCE", `${WORD}\sworld`))
+	checkErr(t, g.Compile())
+
+	rx, err := g.Rx("SENTENCE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rx.MatchString("hello world") {
+		t.Errorf("Rx(SENTENCE).MatchString(%q) = false, want true, WORD's FullMatch must not anchor the interpolation site", "hello world")
+	}
+}
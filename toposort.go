@@ -1,6 +1,9 @@
 package grammar
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ##################################################
 // simple toposort algorithm
@@ -14,6 +17,10 @@ type (
 	links map[ruleName]struct{}
 )
 
+// errCyclic is wrapped into the error returned by toposort when the rules
+// of a grammar have a cyclic dependency and can't be ordered.
+var errCyclic = errors.New("cyclic dependency")
+
 // toposort returns all dependent rules in topological sort order.
 func (g *Grammar) toposort() ([]ruleName, error) {
 	// fill dag datastruct, nodes with links aka rules with subrules